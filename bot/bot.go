@@ -11,19 +11,29 @@ import (
 	"golang.org/x/sync/errgroup"
 	"log"
 	"net/url"
+	"ntfy-bot/bot/command"
 	"ntfy-bot/client"
 	"ntfy-bot/config"
 	"ntfy-bot/util"
+	"os/exec"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
+// commandTimeout bounds how long a configured per-topic shell command (see Subscription.Command)
+// may run before it is killed, so a hung command can't wedge message forwarding indefinitely
+const commandTimeout = 10 * time.Second
+
 // Bot is the main struct that provides the bot
 type Bot struct {
 	config    *config.Config
 	conn      conn
 	client *client.Client
 	subscriptions map[string][]string // Topic URL -> Channel IDs
+	commands      map[string]string   // Topic URL -> shell command to pipe incoming messages through
+	registry      map[string]command.Command // Command name -> Command
 	cancelFn  context.CancelFunc
 	mu        sync.RWMutex
 }
@@ -34,15 +44,34 @@ func New(conf *config.Config) (*Bot, error) {
 	switch conf.Platform() {
 	case config.Discord:
 		conn = newDiscordConn(conf)
+	case config.Slack:
+		conn = newSlackConn(conf)
 	default:
 		return nil, fmt.Errorf("invalid type: %s", conf.Platform())
 	}
-	return &Bot{
+	b := &Bot{
 		config:    conf,
 		conn:      conn,
 		client: client.New(),
 		subscriptions: make(map[string][]string),
-	}, nil
+		commands:      make(map[string]string),
+		registry:      make(map[string]command.Command),
+	}
+	b.Register(newPublishCommand(b))
+	b.Register(newSubscribeCommand(b))
+	b.Register(newUnsubscribeCommand(b))
+	b.Register(newScheduledCommand(b))
+	return b, nil
+}
+
+// Register adds a command to the bot's registry, making it invokable from chat by mentioning the
+// bot followed by the command name. Built-in commands (publish/subscribe/unsubscribe/scheduled)
+// are registered this way too, so third
+// parties can add their own (e.g. "stats", "list", "mute") by importing this package.
+func (b *Bot) Register(cmd command.Command) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.registry[cmd.Name()] = cmd
 }
 
 // Run runs the bot in the foreground indefinitely or until Stop is called.
@@ -55,6 +84,7 @@ func (b *Bot) Run() error {
 	if err != nil {
 		return err
 	}
+	b.subscribeStatic()
 	g.Go(func() error {
 		return b.handleChatEvents(ctx, eventChan)
 	})
@@ -71,6 +101,30 @@ func (b *Bot) Stop() {
 	b.cancelFn() // This must be at the end, see app.go
 }
 
+// subscribeStatic subscribes to the topics declared in the config file's "subscriptions" list,
+// mirroring what execSubscribe does for topics requested at runtime via chat.
+func (b *Bot) subscribeStatic() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range b.config.Subscriptions {
+		topicURL := sub.TopicURL(b.config)
+		server := b.config.ResolveServer(sub.Server)
+		token := sub.Auth
+		if token == "" {
+			token = server.Token
+		}
+		log.Printf("Subscribing to %s in channel %s (from config file)", topicURL, sub.Channel)
+		if _, ok := b.subscriptions[topicURL]; !ok {
+			b.client.Subscribe(topicURL, client.WithSubscribeBasicAuth(server.User, server.Password), client.WithSubscribeBearerToken(token))
+			b.subscriptions[topicURL] = make([]string, 0)
+		}
+		b.subscriptions[topicURL] = append(b.subscriptions[topicURL], sub.Channel)
+		if sub.Command != "" {
+			b.commands[topicURL] = sub.Command
+		}
+	}
+}
+
 func (b *Bot) handleChatEvents(ctx context.Context, eventChan <-chan event) error {
 	for {
 		select {
@@ -125,22 +179,74 @@ func (b *Bot) handleSubscriptionMessage(m *client.Message) error {
 	if m.Event != "message" {
 		return nil
 	}
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	topicURL := m.TopicURL
 	log.Printf("Forwarding incoming message to chat: %s", m.Message)
-	topicURL := "https://ntfy.sh/" + m.Topic
+
+	b.mu.RLock()
+	shellCommand, hasCommand := b.commands[topicURL]
+	channels := append([]string(nil), b.subscriptions[topicURL]...)
+	b.mu.RUnlock()
+
 	message := fmt.Sprintf("**%s** %s", util.ShortURL(topicURL), m.Message)
-	if _, ok := b.subscriptions[topicURL]; ok {
-		for _, channel := range b.subscriptions[topicURL] {
-			b.conn.Send(channel, message)
+	if hasCommand {
+		output, err := runCommand(shellCommand, m.Message)
+		if err != nil {
+			log.Printf("command %q for topic %s failed: %s", shellCommand, topicURL, err.Error())
+		} else if output != "" {
+			message = output
 		}
 	}
+	for _, channel := range channels {
+		b.conn.Send(channel, message)
+	}
 	return nil
 }
 
+// runCommand pipes input to the given shell command's stdin and returns its trimmed stdout. The
+// command is killed if it runs longer than commandTimeout, so a hung command can't block the
+// caller indefinitely.
+func runCommand(shellCommand string, input string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", shellCommand)
+	cmd.Stdin = strings.NewReader(input)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 func (b *Bot) runCLI(ev *messageEvent, args []string) error {
 	var buf bytes.Buffer
 
+	b.mu.RLock()
+	names := make([]string, 0, len(b.registry))
+	for name := range b.registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cliCommands := make([]*cli.Command, 0, len(names))
+	for _, name := range names {
+		cmd := b.registry[name]
+		cliCommands = append(cliCommands, &cli.Command{
+			Name:      cmd.Name(),
+			Aliases:   cmd.Aliases(),
+			Usage:     cmd.Usage(),
+			UsageText: fmt.Sprintf("ntfy %s [OPTION..] TOPIC", cmd.Name()),
+			Flags:     cmd.Flags(),
+			Description: cmd.Description(),
+			Action: func(c *cli.Context) error {
+				return cmd.Exec(&command.Context{Context: c, Event: ev, Conn: b.conn, Client: b.client})
+			},
+			OnUsageError: func(c *cli.Context, err error, isSubcommand bool) error {
+				log.Printf("subcommand usage error")
+				return nil
+			},
+		})
+	}
+	b.mu.RUnlock()
+
 	app := &cli.App{
 		Name:                   "ntfy",
 		Usage:                  "Bot for sending and receiving messages to/from ntfy",
@@ -149,55 +255,7 @@ func (b *Bot) runCLI(ev *messageEvent, args []string) error {
 		Reader: &buf,
 		Writer: &buf,
 		ErrWriter: &buf,
-		Commands: []*cli.Command{
-			{
-				Name:      "publish",
-				Aliases:   []string{"send"},
-				Usage:     "xxxxxxx",
-				UsageText: "ntfy send [--server=...] TOPIC",
-				Action:    func (c *cli.Context) error {
-					return b.execPublish(c, ev)
-				},
-				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "server", Aliases: []string{"s"}, Value: b.config.BaseURL, Usage: "server URL"},
-					&cli.StringFlag{Name: "title", Aliases: []string{"t"}, Usage: "message title"},
-					&cli.StringFlag{Name: "priority", Aliases: []string{"p"}, Usage: "message priority (1-5)"},
-					&cli.StringFlag{Name: "tags", Aliases: []string{"ta"}, Usage: "message tags (command separated list)"},
-				},
-				Description: `xxxxxxxxx`,
-				CustomHelpTemplate: "command template",
-				OnUsageError: func(context *cli.Context, err error, isSubcommand bool) error {
-					log.Printf("subcommand usage error")
-					return nil
-				},
-			},
-			{
-				Name:      "subscribe",
-				Aliases:   []string{"sub", "add"},
-				Usage:     "xxxxxxx",
-				UsageText: "ntfy subscribe [--server=...] TOPIC",
-				Action:    func (c *cli.Context) error {
-					return b.execSubscribe(c, ev)
-				},
-				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "server", Aliases: []string{"s"}, Value: b.config.BaseURL, Usage: "server URL"},
-				},
-				Description: `xxxxxxxxx`,
-			},
-			{
-				Name:      "unsubscribe",
-				Aliases:   []string{"del", "rm"},
-				Usage:     "xxxxxxx",
-				UsageText: "ntfy unsubscribe [--server=...] TOPIC",
-				Action:    func (c *cli.Context) error {
-					return b.execUnsubscribe(c, ev)
-				},
-				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "server", Aliases: []string{"s"}, Value: b.config.BaseURL, Usage: "server URL"},
-				},
-				Description: `xxxxxxxxx`,
-			},
-		},
+		Commands: cliCommands,
 		CommandNotFound: func(c *cli.Context, s string) {
 			if err := b.execCommandNotFound(c, ev, s); err != nil {
 				log.Printf("error executing command not found function: %s", err.Error())
@@ -216,64 +274,137 @@ func (b *Bot) runCLI(ev *messageEvent, args []string) error {
 	return err
 }
 
-func (b *Bot) execPublish(c *cli.Context, ev *messageEvent) error {
-	baseURL := c.String("server")
-	title := c.String("title")
-	priority := c.String("priority")
-	tags := c.String("tags")
-	if c.NArg() < 2 {
+// resolveUserPass parses a "user[:pass]" flag value, falling back to the resolved server's
+// configured credentials if the flag was not set
+func (b *Bot) resolveUserPass(flagValue string, server config.ServerConfig) (user string, pass string) {
+	if flagValue == "" {
+		return server.User, server.Password
+	}
+	return util.SplitUserPass(flagValue)
+}
+
+// resolveToken returns the given flag value, falling back to the resolved server's configured
+// access token if the flag was not set
+func (b *Bot) resolveToken(flagValue string, server config.ServerConfig) string {
+	if flagValue == "" {
+		return server.Token
+	}
+	return flagValue
+}
+
+func (b *Bot) execPublish(ctx *command.Context) error {
+	server := b.config.ResolveServer(ctx.String("server"))
+	title := ctx.String("title")
+	priority := ctx.String("priority")
+	tags := ctx.String("tags")
+	delay := ctx.String("delay")
+	user, pass := b.resolveUserPass(ctx.String("user"), server)
+	token := b.resolveToken(ctx.String("token"), server)
+	if ctx.NArg() < 2 {
 		return errors.New("topic and/or message missing")
 	}
-	topic := c.Args().First()
-	topicURL := fmt.Sprintf("%s/%s", baseURL, topic)
-	message := strings.Join(c.Args().Slice()[1:], " ")
-	log.Printf("Publishing to %s from channel %s: %s", topicURL, ev.Channel, message)
-	if err := b.client.Publish(topicURL, message, client.WithTitle(title), client.WithPriority(priority), client.WithTags(tags)); err != nil {
+	topic := ctx.Args().First()
+	topicURL := fmt.Sprintf("%s/%s", server.BaseURL, topic)
+	message := strings.Join(ctx.Args().Slice()[1:], " ")
+	log.Printf("Publishing to %s from channel %s: %s", topicURL, ctx.Event.Channel, message)
+	if err := ctx.Client.Publish(topicURL, message, client.WithTitle(title), client.WithPriority(priority), client.WithTags(tags), client.WithBasicAuth(user, pass), client.WithBearerToken(token), client.WithDelay(delay)); err != nil {
 		return err
 	}
-	return b.conn.React(ev.Channel, ev.ID, url.QueryEscape("👍"))
+	return ctx.Conn.React(ctx.Event.Channel, ctx.Event.ID, url.QueryEscape("👍"))
 }
 
-func (b *Bot) execSubscribe(c *cli.Context, ev *messageEvent) error {
-	baseURL := c.String("server")
-	if c.NArg() < 1 {
+func (b *Bot) execSubscribe(ctx *command.Context) error {
+	server := b.config.ResolveServer(ctx.String("server"))
+	user, pass := b.resolveUserPass(ctx.String("user"), server)
+	token := b.resolveToken(ctx.String("token"), server)
+	since := ctx.String("since")
+	poll := ctx.Bool("poll")
+	scheduled := ctx.Bool("scheduled")
+	if ctx.NArg() < 1 {
 		return errors.New("missing server address, see --help for usage details")
 	}
-	topic := c.Args().First()
-	topicURL := fmt.Sprintf("%s/%s", baseURL, topic)
-	log.Printf("Subscribing to %s in channel %s", topicURL, ev.Channel)
+	topic := ctx.Args().First()
+	topicURL := fmt.Sprintf("%s/%s", server.BaseURL, topic)
+	opts := []client.SubscribeOption{
+		client.WithSubscribeBasicAuth(user, pass),
+		client.WithSubscribeBearerToken(token),
+		client.WithSince(since),
+	}
+	if scheduled {
+		opts = append(opts, client.WithScheduled())
+	}
+	if poll {
+		log.Printf("Polling %s for channel %s", topicURL, ctx.Event.Channel)
+		messages, err := ctx.Client.Poll(topicURL, opts...)
+		if err != nil {
+			return err
+		}
+		for _, m := range messages {
+			if err := ctx.Conn.Send(ctx.Event.Channel, fmt.Sprintf("**%s** %s", util.ShortURL(topicURL), m.Message)); err != nil {
+				return err
+			}
+		}
+		return ctx.Conn.React(ctx.Event.Channel, ctx.Event.ID, url.QueryEscape("👍"))
+	}
+	log.Printf("Subscribing to %s in channel %s", topicURL, ctx.Event.Channel)
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	if _, ok := b.subscriptions[topicURL]; !ok {
-		b.client.Subscribe(topicURL)
+		b.client.Subscribe(topicURL, opts...)
 		b.subscriptions[topicURL] = make([]string, 0)
 	}
-	b.subscriptions[topicURL] = append(b.subscriptions[topicURL], ev.Channel)
-	return b.conn.React(ev.Channel, ev.ID, url.QueryEscape("👍"))
+	b.subscriptions[topicURL] = append(b.subscriptions[topicURL], ctx.Event.Channel)
+	return ctx.Conn.React(ctx.Event.Channel, ctx.Event.ID, url.QueryEscape("👍"))
 }
 
-func (b *Bot) execUnsubscribe(c *cli.Context, ev *messageEvent) error {
-	baseURL := c.String("server")
-	if c.NArg() < 1 {
+func (b *Bot) execUnsubscribe(ctx *command.Context) error {
+	server := b.config.ResolveServer(ctx.String("server"))
+	if ctx.NArg() < 1 {
 		return errors.New("missing server address, see --help for usage details")
 	}
-	topic := c.Args().First()
-	topicURL := fmt.Sprintf("%s/%s", baseURL, topic)
-	log.Printf("Unsubscribing from %s in channel %s", topicURL, ev.Channel)
+	topic := ctx.Args().First()
+	topicURL := fmt.Sprintf("%s/%s", server.BaseURL, topic)
+	log.Printf("Unsubscribing from %s in channel %s", topicURL, ctx.Event.Channel)
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	if _, ok := b.subscriptions[topicURL]; ok {
-		b.subscriptions[topicURL] = util.RemoveString(b.subscriptions[topicURL], ev.Channel)
+		b.subscriptions[topicURL] = util.RemoveString(b.subscriptions[topicURL], ctx.Event.Channel)
 		if len(b.subscriptions[topicURL]) == 0 {
 			log.Printf("No more subscriptions to topic %s; terminating connection", topicURL)
 			b.client.Unsubscribe(topicURL)
 			delete(b.subscriptions, topicURL)
 		}
 	}
-	return b.conn.React(ev.Channel, ev.ID, url.QueryEscape("👍"))
+	return ctx.Conn.React(ctx.Event.Channel, ctx.Event.ID, url.QueryEscape("👍"))
+}
+
+func (b *Bot) execScheduled(ctx *command.Context) error {
+	server := b.config.ResolveServer(ctx.String("server"))
+	user, pass := b.resolveUserPass(ctx.String("user"), server)
+	token := b.resolveToken(ctx.String("token"), server)
+	if ctx.NArg() < 1 {
+		return errors.New("missing topic, see --help for usage details")
+	}
+	topic := ctx.Args().First()
+	topicURL := fmt.Sprintf("%s/%s", server.BaseURL, topic)
+	log.Printf("Listing scheduled messages for %s in channel %s", topicURL, ctx.Event.Channel)
+	messages, err := ctx.Client.Poll(topicURL, client.WithSubscribeBasicAuth(user, pass), client.WithSubscribeBearerToken(token), client.WithScheduled())
+	if err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	for _, m := range messages {
+		if m.Event != "message" || m.Time <= now {
+			continue // Skip non-message events and messages that were already delivered
+		}
+		scheduledAt := time.Unix(m.Time, 0).Format(time.RFC1123)
+		if err := ctx.Conn.Send(ctx.Event.Channel, fmt.Sprintf("**%s** %s (scheduled for %s)", util.ShortURL(topicURL), m.Message, scheduledAt)); err != nil {
+			return err
+		}
+	}
+	return ctx.Conn.React(ctx.Event.Channel, ctx.Event.ID, url.QueryEscape("👍"))
 }
 
 func (b *Bot) execCommandNotFound(c *cli.Context, ev *messageEvent, s string) error {
 	return b.conn.Send(ev.Channel, "command not found: " + s)
 }
-
@@ -0,0 +1,60 @@
+// Package command defines the plugin interface used to register ntfybot chat commands.
+// Third parties can implement Command and register it with a bot.Bot to add new commands
+// (e.g. "stats", "list", "mute") without touching the bot package itself.
+package command
+
+import (
+	"github.com/urfave/cli/v2"
+	"ntfy-bot/client"
+)
+
+// MessageEvent represents an incoming chat message that triggered a command
+type MessageEvent struct {
+	ID      string
+	Channel string
+	User    string
+	Message string
+	File    []byte // used for tests only
+}
+
+// Conn is the subset of a chat platform connection that commands are allowed to use to reply
+type Conn interface {
+	Send(channel string, message string) error
+	SendWithID(channel string, message string) (string, error)
+	React(channelID string, messageID, emoji string) error
+	MentionBot() string
+	Mention(user string) string
+	ParseMention(user string) (string, error)
+	Unescape(s string) string
+	Close() error
+}
+
+// Context carries everything a Command needs to execute: the parsed flags/args, the chat
+// message that triggered it, the connection to reply on, and the ntfy client.
+type Context struct {
+	*cli.Context
+	Event  *MessageEvent
+	Conn   Conn
+	Client *client.Client
+}
+
+// Command is a chat command that can be registered with a Bot via Bot.Register
+type Command interface {
+	// Name returns the command's primary name, used to invoke it from chat
+	Name() string
+
+	// Aliases returns alternative names this command can be invoked by
+	Aliases() []string
+
+	// Flags returns the command line flags accepted by this command
+	Flags() []cli.Flag
+
+	// Usage returns a one-line usage summary, shown in the command listing
+	Usage() string
+
+	// Description returns the long-form help text for this command
+	Description() string
+
+	// Exec runs the command
+	Exec(ctx *Context) error
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"github.com/urfave/cli/v2"
+	"ntfy-bot/bot/command"
+)
+
+// publishCommand is the built-in "publish"/"send" command
+type publishCommand struct {
+	bot *Bot
+}
+
+func newPublishCommand(b *Bot) command.Command {
+	return &publishCommand{bot: b}
+}
+
+func (*publishCommand) Name() string       { return "publish" }
+func (*publishCommand) Aliases() []string  { return []string{"send"} }
+func (*publishCommand) Usage() string      { return "xxxxxxx" }
+func (*publishCommand) Description() string { return `xxxxxxxxx` }
+
+func (p *publishCommand) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "server", Aliases: []string{"s"}, DefaultText: "default server", Usage: "server URL or configured alias"},
+		&cli.StringFlag{Name: "title", Aliases: []string{"t"}, Usage: "message title"},
+		&cli.StringFlag{Name: "priority", Aliases: []string{"p"}, Usage: "message priority (1-5)"},
+		&cli.StringFlag{Name: "tags", Aliases: []string{"ta"}, Usage: "message tags (command separated list)"},
+		&cli.StringFlag{Name: "user", Aliases: []string{"u"}, EnvVars: []string{"NTFY_USER"}, Usage: "username[:password], for protected topics"},
+		&cli.StringFlag{Name: "token", Aliases: []string{"k"}, EnvVars: []string{"NTFY_TOKEN"}, Usage: "access token, for protected topics"},
+		&cli.StringFlag{Name: "delay", Aliases: []string{"d"}, Usage: "delay delivery, e.g. '30m' or 'tomorrow 10am'"},
+	}
+}
+
+func (p *publishCommand) Exec(ctx *command.Context) error {
+	return p.bot.execPublish(ctx)
+}
+
+// subscribeCommand is the built-in "subscribe"/"sub"/"add" command
+type subscribeCommand struct {
+	bot *Bot
+}
+
+func newSubscribeCommand(b *Bot) command.Command {
+	return &subscribeCommand{bot: b}
+}
+
+func (*subscribeCommand) Name() string       { return "subscribe" }
+func (*subscribeCommand) Aliases() []string  { return []string{"sub", "add"} }
+func (*subscribeCommand) Usage() string      { return "xxxxxxx" }
+func (*subscribeCommand) Description() string { return `xxxxxxxxx` }
+
+func (s *subscribeCommand) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "server", DefaultText: "default server", Usage: "server URL or configured alias"},
+		&cli.StringFlag{Name: "user", Aliases: []string{"u"}, EnvVars: []string{"NTFY_USER"}, Usage: "username[:password], for protected topics"},
+		&cli.StringFlag{Name: "token", Aliases: []string{"k"}, EnvVars: []string{"NTFY_TOKEN"}, Usage: "access token, for protected topics"},
+		&cli.BoolFlag{Name: "poll", Aliases: []string{"p"}, Usage: "poll for messages and exit, instead of subscribing"},
+		&cli.StringFlag{Name: "since", Aliases: []string{"s"}, Usage: "return messages since Unix timestamp, duration (10m), 'all', or a message ID"},
+		&cli.BoolFlag{Name: "scheduled", Aliases: []string{"S"}, Usage: "also return delayed/pending scheduled messages"},
+	}
+}
+
+func (s *subscribeCommand) Exec(ctx *command.Context) error {
+	return s.bot.execSubscribe(ctx)
+}
+
+// unsubscribeCommand is the built-in "unsubscribe"/"del"/"rm" command
+type unsubscribeCommand struct {
+	bot *Bot
+}
+
+func newUnsubscribeCommand(b *Bot) command.Command {
+	return &unsubscribeCommand{bot: b}
+}
+
+func (*unsubscribeCommand) Name() string       { return "unsubscribe" }
+func (*unsubscribeCommand) Aliases() []string  { return []string{"del", "rm"} }
+func (*unsubscribeCommand) Usage() string      { return "xxxxxxx" }
+func (*unsubscribeCommand) Description() string { return `xxxxxxxxx` }
+
+func (u *unsubscribeCommand) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "server", Aliases: []string{"s"}, DefaultText: "default server", Usage: "server URL or configured alias"},
+	}
+}
+
+func (u *unsubscribeCommand) Exec(ctx *command.Context) error {
+	return u.bot.execUnsubscribe(ctx)
+}
+
+// scheduledCommand is the built-in "scheduled"/"sched" command, listing pending scheduled
+// (delayed) messages for a topic
+type scheduledCommand struct {
+	bot *Bot
+}
+
+func newScheduledCommand(b *Bot) command.Command {
+	return &scheduledCommand{bot: b}
+}
+
+func (*scheduledCommand) Name() string       { return "scheduled" }
+func (*scheduledCommand) Aliases() []string  { return []string{"sched"} }
+func (*scheduledCommand) Usage() string      { return "xxxxxxx" }
+func (*scheduledCommand) Description() string { return `xxxxxxxxx` }
+
+func (s *scheduledCommand) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "server", Aliases: []string{"s"}, DefaultText: "default server", Usage: "server URL or configured alias"},
+		&cli.StringFlag{Name: "user", Aliases: []string{"u"}, EnvVars: []string{"NTFY_USER"}, Usage: "username[:password], for protected topics"},
+		&cli.StringFlag{Name: "token", Aliases: []string{"k"}, EnvVars: []string{"NTFY_TOKEN"}, Usage: "access token, for protected topics"},
+	}
+}
+
+func (s *scheduledCommand) Exec(ctx *command.Context) error {
+	return s.bot.execScheduled(ctx)
+}
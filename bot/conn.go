@@ -2,16 +2,11 @@ package bot
 
 import (
 	"context"
+	"ntfy-bot/bot/command"
 )
 
+// conn is an alias for command.Conn plus Connect, which only the bot's main loop needs
 type conn interface {
+	command.Conn
 	Connect(ctx context.Context) (<-chan event, error)
-	Send(channel string, message string) error
-	SendWithID(channel string, message string) (string, error)
-	React(channelID string, messageID, emoji string) error
-	MentionBot() string
-	Mention(user string) string
-	ParseMention(user string) (string, error)
-	Unescape(s string) string
-	Close() error
 }
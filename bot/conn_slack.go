@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/slack-go/slack"
+	"log"
+	"net/url"
+	"ntfy-bot/config"
+	"regexp"
+	"sync"
+)
+
+var (
+	slackUserLinkRegex  = regexp.MustCompile(`<@([^>|]+)(?:\|[^>]+)?>`)
+	slackCodeBlockRegex = regexp.MustCompile("```([^`]+)```")
+	slackCodeRegex      = regexp.MustCompile("`([^`]+)`")
+
+	// slackEmojiNames maps the (unicode) emoji used by bot.go's call sites to the short code
+	// name Slack's reactions.add API expects, since conn.React's contract passes the
+	// Discord-shaped raw/percent-encoded emoji to every backend.
+	slackEmojiNames = map[string]string{
+		"👍": "thumbsup",
+	}
+)
+
+type slackConn struct {
+	config *config.Config
+	client *slack.Client
+	rtm    *slack.RTM
+	userID string
+	mu     sync.Mutex
+}
+
+func newSlackConn(conf *config.Config) *slackConn {
+	return &slackConn{
+		config: conf,
+		client: slack.New(conf.Token),
+	}
+}
+
+func (c *slackConn) Connect(ctx context.Context) (<-chan event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rtm = c.client.NewRTM()
+	go c.rtm.ManageConnection()
+	eventChan := make(chan event)
+	go c.handleRTMEvents(ctx, eventChan)
+	return eventChan, nil
+}
+
+func (c *slackConn) handleRTMEvents(ctx context.Context, eventChan chan event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-c.rtm.IncomingEvents:
+			switch ev := msg.Data.(type) {
+			case *slack.ConnectedEvent:
+				c.mu.Lock()
+				c.userID = ev.Info.User.ID
+				c.mu.Unlock()
+				log.Printf("Slack connected as user %s", c.userID)
+			case *slack.MessageEvent:
+				if translated := c.translateMessageEvent(ev); translated != nil {
+					eventChan <- translated
+				}
+			case *slack.RTMError:
+				eventChan <- &errorEvent{Error: errors.New(ev.Error())}
+			}
+		}
+	}
+}
+
+func (c *slackConn) Send(channel string, message string) error {
+	_, err := c.SendWithID(channel, message)
+	return err
+}
+
+func (c *slackConn) SendWithID(channel string, message string) (string, error) {
+	_, timestamp, err := c.client.PostMessage(channel, slack.MsgOptionText(message, false))
+	if err != nil {
+		return "", err
+	}
+	return timestamp, nil
+}
+
+func (c *slackConn) React(channelID string, messageID, emoji string) error {
+	name, ok := slackEmojiName(emoji)
+	if !ok {
+		// Not every emoji a command might react with has a known Slack short name (see
+		// slackEmojiNames); skip the reaction rather than failing the whole command over it.
+		log.Printf("no Slack emoji name known for %s, skipping reaction", emoji)
+		return nil
+	}
+	return c.client.AddReaction(name, slack.NewRefToMessage(channelID, messageID))
+}
+
+// slackEmojiName resolves emoji, a (possibly percent-encoded) unicode emoji as passed to
+// conn.React, to the short code name Slack's reactions.add API expects.
+func slackEmojiName(emoji string) (string, bool) {
+	decoded, err := url.QueryUnescape(emoji)
+	if err != nil {
+		decoded = emoji
+	}
+	name, ok := slackEmojiNames[decoded]
+	if !ok {
+		return "", false
+	}
+	return name, true
+}
+
+func (c *slackConn) Close() error {
+	if c.rtm == nil {
+		return nil
+	}
+	return c.rtm.Disconnect()
+}
+
+func (c *slackConn) MentionBot() string {
+	return fmt.Sprintf("<@%s>", c.userID)
+}
+
+func (c *slackConn) Mention(user string) string {
+	return fmt.Sprintf("<@%s>", user)
+}
+
+func (c *slackConn) ParseMention(user string) (string, error) {
+	if matches := slackUserLinkRegex.FindStringSubmatch(user); len(matches) > 0 {
+		return matches[1], nil
+	}
+	return "", errors.New("invalid user")
+}
+
+func (c *slackConn) Unescape(s string) string {
+	s = slackCodeBlockRegex.ReplaceAllString(s, "$1")
+	s = slackCodeRegex.ReplaceAllString(s, "$1")
+	s = slackUserLinkRegex.ReplaceAllString(s, "") // Remove entirely!
+	return s
+}
+
+func (c *slackConn) translateMessageEvent(m *slack.MessageEvent) event {
+	if m.User == "" || m.User == c.userID || m.SubType != "" {
+		return nil
+	}
+	return &messageEvent{
+		ID:      m.Timestamp,
+		Channel: m.Channel,
+		User:    m.User,
+		Message: m.Text,
+	}
+}
@@ -1,14 +1,12 @@
 package bot
 
+import "ntfy-bot/bot/command"
+
 type event interface{}
 
-type messageEvent struct {
-	ID          string
-	Channel     string
-	User        string
-	Message     string
-	File        []byte // used for tests only
-}
+// messageEvent is an alias for command.MessageEvent so the bot package's internal event
+// handling and the bot/command plugin API share a single definition.
+type messageEvent = command.MessageEvent
 
 type channelJoinedEvent struct {
 	Channel string
@@ -17,4 +15,3 @@ type channelJoinedEvent struct {
 type errorEvent struct {
 	Error error
 }
-
@@ -19,12 +19,19 @@ type Client struct {
 }
 
 type Message struct {
+	ID       string
+	Time     int64
 	Event string
 	Topic string
 	Message string
 	Title string
 	Priority int
 	Tags []string
+
+	// TopicURL is the fully qualified topic URL this message was received for, as passed to
+	// Subscribe/Poll. It is set by the client, not the server, so callers can recover which
+	// configured server a message came from without assuming a single global host.
+	TopicURL string
 }
 
 type subscription struct {
@@ -55,7 +62,37 @@ func (c *Client) Publish(topicURL string, message string, options ...MessageOpti
 	return err
 }
 
-func (c *Client) Subscribe(topicURL string) {
+// Poll fetches all messages currently available for the topic without opening a long-lived
+// connection, using ntfy's poll mode (?poll=1)
+func (c *Client) Poll(topicURL string, opts ...SubscribeOption) ([]*Message, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/json?poll=1", topicURL), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt(req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var messages []*Message
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var m *Message
+		if err := json.NewDecoder(strings.NewReader(scanner.Text())).Decode(&m); err != nil {
+			return nil, err
+		}
+		m.TopicURL = topicURL
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+func (c *Client) Subscribe(topicURL string, opts ...SubscribeOption) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if _, ok := c.subscriptions[topicURL]; ok {
@@ -63,7 +100,7 @@ func (c *Client) Subscribe(topicURL string) {
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	c.subscriptions[topicURL] = &subscription{cancel}
-	go handleConnectionLoop(ctx, c.Messages, topicURL)
+	go handleConnectionLoop(ctx, c.Messages, topicURL, opts...)
 }
 
 func (c *Client) Unsubscribe(topicURL string) {
@@ -77,9 +114,14 @@ func (c *Client) Unsubscribe(topicURL string) {
 	return
 }
 
-func handleConnectionLoop(ctx context.Context, msgChan chan *Message, topicURL string) {
+func handleConnectionLoop(ctx context.Context, msgChan chan *Message, topicURL string, opts ...SubscribeOption) {
+	var lastID string
 	for {
-		if err := handleConnection(ctx, msgChan, topicURL); err != nil {
+		id, err := handleConnection(ctx, msgChan, topicURL, lastID, opts...)
+		if id != "" {
+			lastID = id
+		}
+		if err != nil {
 			log.Printf("connection to %s failed: %s", topicURL, err.Error())
 		}
 		select {
@@ -91,23 +133,42 @@ func handleConnectionLoop(ctx context.Context, msgChan chan *Message, topicURL s
 	}
 }
 
-func handleConnection(ctx context.Context, msgChan chan *Message, topicURL string) error {
+// handleConnection opens a single streaming connection to the topic and forwards every message
+// to msgChan. If since is set (e.g. because a previous connection dropped), it overrides any
+// "since" value from opts so no messages are lost across the reconnect. It returns the ID of the
+// last message seen, so the caller can resume from there on the next reconnect.
+func handleConnection(ctx context.Context, msgChan chan *Message, topicURL string, since string, opts ...SubscribeOption) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/json", topicURL), nil)
 	if err != nil {
-		return err
+		return "", err
+	}
+	for _, opt := range opts {
+		if err := opt(req); err != nil {
+			return "", err
+		}
+	}
+	if since != "" {
+		q := req.URL.Query()
+		q.Set("since", since)
+		req.URL.RawQuery = q.Encode()
 	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
+	var lastID string
 	scanner := bufio.NewScanner(resp.Body)
 	for scanner.Scan() {
 		var m *Message
 		if err := json.NewDecoder(strings.NewReader(scanner.Text())).Decode(&m); err != nil {
-			return err
+			return lastID, err
 		}
+		m.TopicURL = topicURL
 		msgChan <- m
+		if m.ID != "" {
+			lastID = m.ID
+		}
 	}
-	return nil
+	return lastID, nil
 }
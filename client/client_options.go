@@ -30,3 +30,81 @@ func WithTags(tags string) MessageOption {
 		return nil
 	}
 }
+
+func WithBasicAuth(user, pass string) MessageOption {
+	return func(r *http.Request) error {
+		applyBasicAuth(r, user, pass)
+		return nil
+	}
+}
+
+func WithBearerToken(token string) MessageOption {
+	return func(r *http.Request) error {
+		applyBearerToken(r, token)
+		return nil
+	}
+}
+
+// WithDelay sets the X-Delay header, scheduling the message for future delivery (e.g. "30m",
+// "tomorrow 10am")
+func WithDelay(delay string) MessageOption {
+	return func(r *http.Request) error {
+		if delay != "" {
+			r.Header.Set("X-Delay", delay)
+		}
+		return nil
+	}
+}
+
+// SubscribeOption is a functional option applied to a subscribe/poll request
+type SubscribeOption func(r *http.Request) error
+
+func WithSubscribeBasicAuth(user, pass string) SubscribeOption {
+	return func(r *http.Request) error {
+		applyBasicAuth(r, user, pass)
+		return nil
+	}
+}
+
+func WithSubscribeBearerToken(token string) SubscribeOption {
+	return func(r *http.Request) error {
+		applyBearerToken(r, token)
+		return nil
+	}
+}
+
+// WithSince sets the "since" query parameter, which may be a Unix timestamp, a duration
+// (e.g. "10m"), "all", or a message ID
+func WithSince(since string) SubscribeOption {
+	return func(r *http.Request) error {
+		if since != "" {
+			q := r.URL.Query()
+			q.Set("since", since)
+			r.URL.RawQuery = q.Encode()
+		}
+		return nil
+	}
+}
+
+// WithScheduled adds the "sched=1" query parameter, so delayed/pending messages are returned
+// alongside regular ones
+func WithScheduled() SubscribeOption {
+	return func(r *http.Request) error {
+		q := r.URL.Query()
+		q.Set("sched", "1")
+		r.URL.RawQuery = q.Encode()
+		return nil
+	}
+}
+
+func applyBasicAuth(r *http.Request, user, pass string) {
+	if user != "" {
+		r.SetBasicAuth(user, pass)
+	}
+}
+
+func applyBearerToken(r *http.Request, token string) {
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+}
@@ -43,6 +43,7 @@ func execRun(c *cli.Context) error {
 	// Read all the options
 	token := c.String("bot-token")
 	debug := c.Bool("debug")
+	configFile := c.String("config")
 
 	// Validate options
 	if token == "" || token == "MUST_BE_SET" {
@@ -52,6 +53,14 @@ func execRun(c *cli.Context) error {
 	// Create main bot
 	conf := config.New(token)
 	conf.Debug = debug
+	if util.FileExists(configFile) {
+		subscriptions, servers, err := config.LoadFileConfig(configFile)
+		if err != nil {
+			return err
+		}
+		conf.Subscriptions = subscriptions
+		conf.Servers = servers
+	}
 	robot, err := bot.New(conf)
 	if err != nil {
 		return err
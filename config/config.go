@@ -1,7 +1,12 @@
 // Package config provides the main configuration for ntfybot
 package config
 
-import "strings"
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"strings"
+)
 
 // Platform defines the target chat application platform
 type Platform string
@@ -21,6 +26,59 @@ type Config struct {
 	Token              string
 	BaseURL string
 	Debug              bool
+	Subscriptions []Subscription
+	Servers       map[string]ServerConfig
+}
+
+// ServerConfig describes a single ntfy server the bot can publish/subscribe to, keyed by a
+// short alias in Config.Servers. This mirrors the ntfy CLI's "default-host"/"auth" config layout,
+// letting one bot instance talk to multiple ntfy servers with distinct credentials.
+type ServerConfig struct {
+	BaseURL  string `yaml:"base_url"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Token    string `yaml:"token"`
+	Default  bool   `yaml:"default"`
+}
+
+// Subscription is a declarative subscription loaded from the bot.yml config file. It is
+// subscribed to automatically on startup, mirroring the ntfy CLI's "--from-config" service mode.
+type Subscription struct {
+	Topic   string `yaml:"topic_url"`
+	Channel string `yaml:"channel_id"`
+	Server  string `yaml:"server"`
+	Auth    string `yaml:"auth"`
+	Command string `yaml:"command"`
+}
+
+// TopicURL returns the fully qualified topic URL for this subscription, resolving a bare topic
+// name against the subscription's server (an alias or URL, see Config.ResolveServer)
+func (s *Subscription) TopicURL(conf *Config) string {
+	if strings.HasPrefix(s.Topic, "http://") || strings.HasPrefix(s.Topic, "https://") {
+		return s.Topic
+	}
+	server := conf.ResolveServer(s.Server)
+	return fmt.Sprintf("%s/%s", server.BaseURL, s.Topic)
+}
+
+// fileConfig mirrors the subset of the YAML config file that cannot be expressed as CLI flags
+type fileConfig struct {
+	Subscriptions []Subscription          `yaml:"subscriptions"`
+	Servers       map[string]ServerConfig `yaml:"servers"`
+}
+
+// LoadFileConfig reads the declarative "subscriptions" and "servers" sections from the given
+// YAML config file
+func LoadFileConfig(filename string) ([]Subscription, map[string]ServerConfig, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	var fc fileConfig
+	if err := yaml.Unmarshal(contents, &fc); err != nil {
+		return nil, nil, err
+	}
+	return fc.Subscriptions, fc.Servers, nil
 }
 
 // New instantiates a default new config
@@ -40,3 +98,26 @@ func (c *Config) Platform() Platform {
 	}
 	return Discord
 }
+
+// DefaultServer returns the ServerConfig marked as default in Servers, or one backed by the
+// plain BaseURL if none is configured
+func (c *Config) DefaultServer() ServerConfig {
+	for _, server := range c.Servers {
+		if server.Default {
+			return server
+		}
+	}
+	return ServerConfig{BaseURL: c.BaseURL}
+}
+
+// ResolveServer resolves a "--server" value, which may be a configured alias or a full URL, to
+// a ServerConfig. An empty value resolves to the default server.
+func (c *Config) ResolveServer(alias string) ServerConfig {
+	if alias == "" {
+		return c.DefaultServer()
+	}
+	if server, ok := c.Servers[alias]; ok {
+		return server
+	}
+	return ServerConfig{BaseURL: alias}
+}
@@ -28,3 +28,12 @@ func RemoveString(s []string, r string) []string {
 func ShortURL(s string) string {
 	return strings.TrimPrefix(strings.TrimPrefix(s, "http://"), "https://")
 }
+
+// SplitUserPass splits a "user[:pass]" string into its user and password parts
+func SplitUserPass(s string) (user string, pass string) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}